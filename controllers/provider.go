@@ -0,0 +1,75 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casibase/casibase/object"
+)
+
+// GetOAuthUrl handles GET /api/get-oauth-url?id=<owner>/<name>, returning the
+// URL the frontend should redirect the user to in order to grant casibase
+// access to an OAuth-based cloud drive Provider (Google Drive, Dropbox,
+// OneDrive). id is echoed back as the OAuth state so OAuthCallback knows
+// which Provider to attach the resulting token to.
+func (c *ApiController) GetOAuthUrl() {
+	id := c.Input().Get("id")
+
+	provider, err := object.GetProvider(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if provider == nil {
+		c.ResponseError(fmt.Sprintf("provider %s does not exist", id))
+		return
+	}
+
+	authUrl, err := provider.GetOAuthAuthURL(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(authUrl)
+}
+
+// OAuthCallback handles GET/POST /api/oauth-callback?state=<owner>/<name>&code=<code>,
+// the redirect target the OAuth provider sends the user back to once they've
+// granted (or denied) access. state carries the Provider id GetOAuthUrl
+// handed out; code is exchanged for an access/refresh token pair that's
+// persisted onto that Provider.
+func (c *ApiController) OAuthCallback() {
+	state := c.Input().Get("state")
+	code := c.Input().Get("code")
+
+	provider, err := object.GetProvider(state)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if provider == nil {
+		c.ResponseError(fmt.Sprintf("provider %s does not exist", state))
+		return
+	}
+
+	if err := provider.HandleOAuthCallback(code); err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/util"
+)
+
+// RefreshStoreVectorsStream handles
+// GET /api/refresh-store-vectors/stream?id=<owner>/<name>, relaying the
+// refresh's progress events to the client as Server-Sent Events until it
+// finishes, errors, or the client disconnects.
+func (c *ApiController) RefreshStoreVectorsStream() {
+	id := c.Input().Get("id")
+
+	progressCh, err := object.RefreshStoreVectorsAsync(id, c.GetSessionUsername())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	flusher, ok := c.Ctx.ResponseWriter.ResponseWriter.(http.Flusher)
+	if !ok {
+		c.ResponseError("streaming is not supported by this response writer")
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/event-stream")
+	c.Ctx.Output.Header("Cache-Control", "no-cache")
+	c.Ctx.Output.Header("Connection", "keep-alive")
+
+	notify := c.Ctx.Request.Context().Done()
+	for {
+		select {
+		case progress, ok := <-progressCh:
+			if !ok {
+				return
+			}
+
+			data, err := util.StructToJson(progress)
+			if err != nil {
+				continue
+			}
+
+			fmt.Fprintf(c.Ctx.ResponseWriter, "data: %s\n\n", data)
+			flusher.Flush()
+
+			if progress.Done {
+				return
+			}
+		case <-notify:
+			return
+		}
+	}
+}
+
+// CancelRefreshStoreVectors handles POST /api/cancel-refresh-store-vectors?id=<owner>/<name>.
+func (c *ApiController) CancelRefreshStoreVectors() {
+	id := c.Input().Get("id")
+
+	err := object.CancelRefresh(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// GetRefreshStoreVectorsStatus handles GET /api/get-refresh-store-vectors-status?id=<owner>/<name>.
+func (c *ApiController) GetRefreshStoreVectorsStatus() {
+	id := c.Input().Get("id")
+
+	c.ResponseOk(object.GetRefreshStatus(id))
+}
+
+// QueryStoreVectors handles GET /api/query-store-vectors?id=<owner>/<name>&text=<query>,
+// the RAG retrieval path: it re-checks the requesting user's read permission
+// on each candidate vector's source file (see object.QueryStoreVectors), so a file a user
+// can't read never surfaces here even if it was embedded before their access
+// was revoked.
+func (c *ApiController) QueryStoreVectors() {
+	id := c.Input().Get("id")
+	text := c.Input().Get("text")
+
+	store, err := object.GetStore(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if store == nil {
+		c.ResponseError(fmt.Sprintf("store %s does not exist", id))
+		return
+	}
+
+	subject := c.GetSessionUsername()
+	scored, err := object.QueryStoreVectors(c.Ctx.Request.Context(), store, subject, text, 10)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(scored)
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// OpenAiProvider embeds text via OpenAI's `/v1/embeddings` endpoint.
+type OpenAiProvider struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func NewOpenAiProvider(apiKey string, model string) *OpenAiProvider {
+	return &OpenAiProvider{apiKey: apiKey, model: model, client: &http.Client{}}
+}
+
+// QueryVector embeds text via OpenAI, passing ctx through to the HTTP
+// request so canceling it (or its deadline expiring) aborts the call instead
+// of leaking a goroutine waiting on the response.
+func (p *OpenAiProvider) QueryVector(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": p.model,
+		"input": text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("embedding: openai QueryVector failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var res struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 {
+		return nil, fmt.Errorf("embedding: openai returned no embeddings")
+	}
+
+	return res.Data[0].Embedding, nil
+}
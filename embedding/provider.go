@@ -0,0 +1,25 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import "context"
+
+// EmbeddingProvider is implemented by every embedding backend (OpenAI, a
+// local model, ...) a Store can use to turn file content into vectors.
+type EmbeddingProvider interface {
+	// QueryVector embeds text, honoring ctx's cancellation/deadline so an
+	// in-flight HTTP call can be torn down instead of leaking goroutines.
+	QueryVector(ctx context.Context, text string) ([]float32, error)
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+)
+
+type subjectContextKey struct{}
+
+// WithSubject returns a copy of ctx carrying subject, the logged-in user's
+// Casdoor name, so it can be threaded through RefreshStoreVectors down to
+// the permission check without every call site having to pass it as an
+// explicit parameter.
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectContextKey{}, subject)
+}
+
+// SubjectFromContext returns the subject stashed by WithSubject, or "" if none was set.
+func SubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectContextKey{}).(string)
+	return subject
+}
+
+// Enforcer is the Casbin enforcer consulted to decide whether a subject may
+// read a given file, keyed the same way as the rest of casibase's
+// permission checks: (subject, storeId, fileKey, action). It is nil until
+// the server wires up its Casdoor-backed model and policy adapter; a nil
+// Enforcer means every read is allowed, so existing stores that never
+// configured permissions keep working unchanged.
+var Enforcer *casbin.Enforcer
+
+// CanReadFile reports whether subject may read the file at fileKey in the
+// store identified by storeId.
+func CanReadFile(subject string, storeId string, fileKey string) bool {
+	if Enforcer == nil {
+		return true
+	}
+
+	ok, err := Enforcer.Enforce(subject, storeId, fileKey, "read")
+	if err != nil {
+		return false
+	}
+	return ok
+}
+
+// FilterFilesForSubject returns those of files that subject is permitted to
+// read in the store identified by storeId. Each entry is checked by its own
+// Key as given; folders are not descended into.
+func FilterFilesForSubject(storeId string, subject string, files []*File) []*File {
+	permitted := make([]*File, 0, len(files))
+	for _, file := range files {
+		if CanReadFile(subject, storeId, file.Key) {
+			permitted = append(permitted, file)
+		}
+	}
+	return permitted
+}
+
+// aclForFile returns every subject known to Enforcer's policy that is
+// permitted to read fileKey in the store identified by storeId, so it can be
+// snapshotted onto the Vector embedded from that file as metadata. A nil
+// Enforcer (no permissions configured) returns nil, meaning the vector
+// carries no ACL.
+func aclForFile(storeId string, fileKey string) []string {
+	if Enforcer == nil {
+		return nil
+	}
+
+	subjects := Enforcer.GetAllSubjects()
+	permitted := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		if CanReadFile(subject, storeId, fileKey) {
+			permitted = append(permitted, subject)
+		}
+	}
+	return permitted
+}
+
+// canReadVector reports whether subject may read vector, re-checking the
+// live Enforcer against the vector's source file (see CanReadFile) rather
+// than its snapshotted Acl, so a permission revoked after the file was
+// embedded takes effect immediately.
+func canReadVector(subject string, vector *Vector) bool {
+	return CanReadFile(subject, vector.Store, vector.File)
+}
@@ -0,0 +1,290 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/casibase/casibase/conf"
+	"github.com/casibase/casibase/storage"
+)
+
+// EncryptionConfig enables server-side encryption of a Store's files,
+// mirroring S3 bucket SSE: each file's data key is wrapped with a master key
+// derived from the operator secret and KMSKeyID (see resolveMasterKey), for
+// "AES256" and "KMS" alike; no external KMS is called. A nil EncryptionConfig (the default for stores created before
+// this field existed) means files are stored as plaintext.
+type EncryptionConfig struct {
+	Algorithm        string `json:"algorithm"` // "AES256" or "KMS"
+	KMSKeyID         string `json:"kmsKeyId,omitempty"`
+	BucketKeyEnabled bool   `json:"bucketKeyEnabled"`
+}
+
+// ObjectLockConfig enables WORM (write-once-read-many) retention for a
+// Store's files, mirroring S3 object lock. A nil ObjectLockConfig (the
+// default for stores created before this field existed) means no file is
+// locked.
+type ObjectLockConfig struct {
+	Mode            string `json:"mode"` // "GOVERNANCE" or "COMPLIANCE"
+	Days            int    `json:"days"`
+	RetainUntilDate string `json:"retainUntilDate,omitempty"`
+	LegalHold       bool   `json:"legalHold"`
+}
+
+// ErrObjectLocked is returned instead of deleting or expiring a file that is
+// still under retention or legal hold, so the HTTP layer can map it to a 403
+// rather than a generic 500.
+type ErrObjectLocked struct {
+	Key string
+}
+
+func (e *ErrObjectLocked) Error() string {
+	return fmt.Sprintf("object: %s is locked by an object-lock retention policy or legal hold", e.Key)
+}
+
+// retainUntil returns the absolute time a file created at createdTime stops
+// being locked, or the zero time if the config has no age-based retention.
+func (c *ObjectLockConfig) retainUntil(createdTime time.Time) time.Time {
+	if c.RetainUntilDate != "" {
+		if t, err := time.Parse(time.RFC3339, c.RetainUntilDate); err == nil {
+			return t
+		}
+	}
+	if c.Days > 0 {
+		return createdTime.AddDate(0, 0, c.Days)
+	}
+	return time.Time{}
+}
+
+// IsLocked reports whether file is still protected by store's ObjectLockConfig.
+func (store *Store) IsLocked(file *File) bool {
+	if store.ObjectLockConfig == nil {
+		return false
+	}
+	if store.ObjectLockConfig.LegalHold {
+		return true
+	}
+
+	createdTime, _ := time.Parse(time.RFC3339, file.CreatedTime)
+	retainUntil := store.ObjectLockConfig.retainUntil(createdTime)
+	return !retainUntil.IsZero() && time.Now().Before(retainUntil)
+}
+
+// findLockedFile returns the key of the first file in store's FileTree that
+// is still locked, or "" if none are.
+func (store *Store) findLockedFile() string {
+	if store.ObjectLockConfig == nil {
+		return ""
+	}
+
+	for _, file := range listFiles(store.FileTree) {
+		if store.IsLocked(file) {
+			return file.Key
+		}
+	}
+	return ""
+}
+
+// resolveMasterKey derives the 32-byte AES-256 key used to wrap each file's
+// data key, from the operator-configured objectMasterKeySecret plus keyID.
+// objectMasterKeySecret must be set (via the CASIBASE_OBJECTMASTERKEYSECRET
+// environment variable) before any store can use EncryptionConfig: deriving
+// the key from keyID alone would let anyone who can read a store's
+// (non-secret) KMSKeyID reproduce the same key and decrypt its files. This
+// is a seam for plugging in a real external KMS client later; for now both
+// "AES256" and "KMS" derive the key from this one operator secret.
+var resolveMasterKey = func(keyID string) ([]byte, error) {
+	secret := conf.GetConfigString("objectMasterKeySecret")
+	if secret == "" {
+		return nil, fmt.Errorf("object: objectMasterKeySecret is not configured, refusing to encrypt or decrypt")
+	}
+
+	sum := sha256.Sum256([]byte(secret + ":" + keyID))
+	return sum[:], nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealEnvelope generates a random per-file data key, encrypts plaintext with
+// it, then wraps the data key with the store's master key. The wrapped key
+// is prefixed to the returned ciphertext so GetObject can unwrap it again
+// without a side-channel lookup.
+func sealEnvelope(plaintext []byte, config *EncryptionConfig) ([]byte, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+
+	dataGcm, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	dataNonce := make([]byte, dataGcm.NonceSize())
+	if _, err := rand.Read(dataNonce); err != nil {
+		return nil, err
+	}
+	ciphertext := dataGcm.Seal(dataNonce, dataNonce, plaintext, nil)
+
+	masterKey, err := resolveMasterKey(config.KMSKeyID)
+	if err != nil {
+		return nil, err
+	}
+	masterGcm, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	keyNonce := make([]byte, masterGcm.NonceSize())
+	if _, err := rand.Read(keyNonce); err != nil {
+		return nil, err
+	}
+	wrappedDek := masterGcm.Seal(keyNonce, keyNonce, dek, nil)
+
+	envelope := make([]byte, 0, 2+len(wrappedDek)+len(ciphertext))
+	envelope = append(envelope, byte(len(wrappedDek)>>8), byte(len(wrappedDek)))
+	envelope = append(envelope, wrappedDek...)
+	envelope = append(envelope, ciphertext...)
+	return envelope, nil
+}
+
+// openEnvelope reverses sealEnvelope: it unwraps the data key with the
+// store's master key, then decrypts the content with it.
+func openEnvelope(envelope []byte, config *EncryptionConfig) ([]byte, error) {
+	if len(envelope) < 2 {
+		return nil, fmt.Errorf("object: encrypted object is truncated")
+	}
+	wrappedDekLen := int(envelope[0])<<8 | int(envelope[1])
+	envelope = envelope[2:]
+	if len(envelope) < wrappedDekLen {
+		return nil, fmt.Errorf("object: encrypted object is truncated")
+	}
+	wrappedDek, ciphertext := envelope[:wrappedDekLen], envelope[wrappedDekLen:]
+
+	masterKey, err := resolveMasterKey(config.KMSKeyID)
+	if err != nil {
+		return nil, err
+	}
+	masterGcm, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedDek) < masterGcm.NonceSize() {
+		return nil, fmt.Errorf("object: wrapped data key is truncated")
+	}
+	keyNonce, wrappedDek := wrappedDek[:masterGcm.NonceSize()], wrappedDek[masterGcm.NonceSize():]
+	dek, err := masterGcm.Open(nil, keyNonce, wrappedDek, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dataGcm, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < dataGcm.NonceSize() {
+		return nil, fmt.Errorf("object: encrypted object is truncated")
+	}
+	dataNonce, ciphertext := ciphertext[:dataGcm.NonceSize()], ciphertext[dataGcm.NonceSize():]
+	return dataGcm.Open(nil, dataNonce, ciphertext, nil)
+}
+
+// encryptingStorageProvider transparently encrypts file bytes on upload and
+// decrypts them on download, so every other caller (notably
+// RefreshStoreVectors) only ever sees plaintext.
+type encryptingStorageProvider struct {
+	storage.StorageProvider
+	config *EncryptionConfig
+}
+
+// wrapWithEncryption wraps inner so reads and writes go through envelope
+// encryption, unless config is nil or its Algorithm is empty (no encryption
+// configured for the store).
+func wrapWithEncryption(inner storage.StorageProvider, config *EncryptionConfig) storage.StorageProvider {
+	if config == nil || config.Algorithm == "" {
+		return inner
+	}
+	return &encryptingStorageProvider{StorageProvider: inner, config: config}
+}
+
+func (p *encryptingStorageProvider) GetObject(key string) (io.ReadCloser, error) {
+	reader, err := p.StorageProvider.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	envelope, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := openEnvelope(envelope, p.config)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(plaintext)), nil
+}
+
+func (p *encryptingStorageProvider) PutObject(key string, reader io.Reader) error {
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	envelope, err := sealEnvelope(plaintext, p.config)
+	if err != nil {
+		return err
+	}
+
+	return p.StorageProvider.PutObject(key, bytes.NewReader(envelope))
+}
+
+// objectLockStorageProvider refuses to delete any file still under
+// retention or legal hold.
+type objectLockStorageProvider struct {
+	storage.StorageProvider
+	store *Store
+}
+
+// wrapWithObjectLock wraps inner so DeleteObject refuses files still under
+// store's ObjectLockConfig, unless store has no lock configured.
+func wrapWithObjectLock(inner storage.StorageProvider, store *Store) storage.StorageProvider {
+	if store.ObjectLockConfig == nil {
+		return inner
+	}
+	return &objectLockStorageProvider{StorageProvider: inner, store: store}
+}
+
+func (p *objectLockStorageProvider) DeleteObject(key string) error {
+	for _, file := range listFiles(p.store.FileTree) {
+		if file.Key == key && p.store.IsLocked(file) {
+			return &ErrObjectLocked{Key: key}
+		}
+	}
+
+	return p.StorageProvider.DeleteObject(key)
+}
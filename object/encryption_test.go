@@ -0,0 +1,70 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestSealOpenEnvelopeRoundTrip(t *testing.T) {
+	os.Setenv("CASIBASE_OBJECTMASTERKEYSECRET", "test-secret")
+	defer os.Unsetenv("CASIBASE_OBJECTMASTERKEYSECRET")
+
+	config := &EncryptionConfig{Algorithm: "AES256", KMSKeyID: "store1-key"}
+	plaintext := []byte("the quick brown fox jumps over the lazy dog")
+
+	envelope, err := sealEnvelope(plaintext, config)
+	if err != nil {
+		t.Fatalf("sealEnvelope() error = %v", err)
+	}
+	if bytes.Contains(envelope, plaintext) {
+		t.Errorf("sealEnvelope() output contains the plaintext verbatim")
+	}
+
+	decrypted, err := openEnvelope(envelope, config)
+	if err != nil {
+		t.Fatalf("openEnvelope() error = %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("openEnvelope() = %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestSealEnvelopeRequiresMasterKeySecret(t *testing.T) {
+	os.Unsetenv("CASIBASE_OBJECTMASTERKEYSECRET")
+
+	config := &EncryptionConfig{Algorithm: "AES256", KMSKeyID: "store1-key"}
+	if _, err := sealEnvelope([]byte("data"), config); err == nil {
+		t.Errorf("sealEnvelope() should fail when no master key secret is configured")
+	}
+}
+
+func TestOpenEnvelopeWrongKeyFails(t *testing.T) {
+	os.Setenv("CASIBASE_OBJECTMASTERKEYSECRET", "test-secret")
+	defer os.Unsetenv("CASIBASE_OBJECTMASTERKEYSECRET")
+
+	config := &EncryptionConfig{Algorithm: "AES256", KMSKeyID: "store1-key"}
+	envelope, err := sealEnvelope([]byte("data"), config)
+	if err != nil {
+		t.Fatalf("sealEnvelope() error = %v", err)
+	}
+
+	wrongConfig := &EncryptionConfig{Algorithm: "AES256", KMSKeyID: "store2-key"}
+	if _, err := openEnvelope(envelope, wrongConfig); err == nil {
+		t.Errorf("openEnvelope() should fail when the wrong KMSKeyID is used")
+	}
+}
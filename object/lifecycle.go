@@ -0,0 +1,210 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"strings"
+	"time"
+
+	"github.com/casibase/casibase/storage"
+)
+
+// LifecycleAction is the action taken on a File matched by a LifecycleRule.
+type LifecycleAction string
+
+const (
+	LifecycleActionExpire        LifecycleAction = "Expire"
+	LifecycleActionSkipVectorize LifecycleAction = "SkipVectorize"
+	LifecycleActionRequantize    LifecycleAction = "Requantize"
+	LifecycleActionArchive       LifecycleAction = "Archive"
+)
+
+// LifecycleFilter selects the files a LifecycleRule applies to. All non-zero
+// fields must match (an AND combinator), mirroring S3 lifecycle filters.
+type LifecycleFilter struct {
+	Prefix  string            `json:"prefix"`
+	MinSize int64             `json:"minSize"`
+	MaxSize int64             `json:"maxSize"`
+	Tags    map[string]string `json:"tags"`
+}
+
+// matches reports whether file satisfies every non-zero condition in f. The
+// file's tags are taken from the Store's PropertiesMap, since File itself
+// carries no tags.
+func (f *LifecycleFilter) matches(file *File, properties *Properties) bool {
+	if f.Prefix != "" && !strings.HasPrefix(file.Key, f.Prefix) {
+		return false
+	}
+	if f.MinSize > 0 && file.Size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && file.Size > f.MaxSize {
+		return false
+	}
+
+	for key, value := range f.Tags {
+		if properties == nil {
+			return false
+		}
+		if key == "subject" {
+			if properties.Subject != value {
+				return false
+			}
+			continue
+		}
+		// Unknown tag keys never match, since Properties only models "subject"
+		// today; this keeps the filter conservative rather than silently true.
+		return false
+	}
+
+	return true
+}
+
+// LifecycleRule is one ordered entry in a Store's LifecycleConfig. Exactly
+// one of Days or Date should be set for rules whose Action is age-based
+// (Expire, Archive); SkipVectorize and Requantize apply to every file the
+// Filter matches, ignoring the age fields.
+type LifecycleRule struct {
+	Id     string          `json:"id"`
+	Filter LifecycleFilter `json:"filter"`
+	Action LifecycleAction `json:"action"`
+	Days   int             `json:"days"`
+	Date   string          `json:"date"`
+}
+
+// LifecycleConfig is an ordered list of LifecycleRules, evaluated first-match-wins.
+type LifecycleConfig struct {
+	Rules []*LifecycleRule `json:"rules"`
+}
+
+// dueDate returns the absolute time after which rule fires for a file
+// created at createdTime, or the zero time if the rule has no age threshold.
+func (rule *LifecycleRule) dueDate(createdTime time.Time) time.Time {
+	if rule.Date != "" {
+		date, err := time.Parse(time.RFC3339, rule.Date)
+		if err != nil {
+			return time.Time{}
+		}
+		return date
+	}
+
+	if rule.Days > 0 {
+		return createdTime.AddDate(0, 0, rule.Days)
+	}
+
+	return time.Time{}
+}
+
+// EvaluateLifecycle returns the first rule in store's LifecycleConfig whose
+// filter matches file, or nil if none do. Age-based rules (Expire, Archive)
+// only match once their due date has passed; SkipVectorize and Requantize
+// match as soon as the filter does, regardless of age.
+func EvaluateLifecycle(store *Store, file *File) *LifecycleRule {
+	if store.LifecycleConfig == nil {
+		return nil
+	}
+
+	var properties *Properties
+	if store.PropertiesMap != nil {
+		properties = store.PropertiesMap[file.Key]
+	}
+
+	createdTime, _ := time.Parse(time.RFC3339, file.CreatedTime)
+
+	for _, rule := range store.LifecycleConfig.Rules {
+		if !rule.Filter.matches(file, properties) {
+			continue
+		}
+
+		switch rule.Action {
+		case LifecycleActionExpire, LifecycleActionArchive:
+			dueDate := rule.dueDate(createdTime)
+			if dueDate.IsZero() || time.Now().Before(dueDate) {
+				continue
+			}
+		}
+
+		return rule
+	}
+
+	return nil
+}
+
+// listFiles flattens a File tree (as stored on Store.FileTree) into its leaves.
+func listFiles(file *File) []*File {
+	if file == nil {
+		return nil
+	}
+
+	if file.IsLeaf {
+		return []*File{file}
+	}
+
+	files := []*File{}
+	for _, child := range file.Children {
+		files = append(files, listFiles(child)...)
+	}
+	return files
+}
+
+// SweepExpiredFiles evaluates store's LifecycleConfig against every file in
+// its FileTree and, for every file an Expire rule matches, deletes the file
+// from the storage provider as well as any vectors embedded from it. In
+// dryRun mode nothing is deleted and the keys that would have been affected
+// are simply returned, so operators can preview a lifecycle config before it
+// runs for real. A file still under the store's ObjectLockConfig is skipped,
+// and not listed among the affected keys, rather than deleted (the storage
+// provider would refuse it with ErrObjectLocked).
+func SweepExpiredFiles(store *Store, dryRun bool) ([]string, error) {
+	affectedKeys := []string{}
+	if store.LifecycleConfig == nil || store.FileTree == nil {
+		return affectedKeys, nil
+	}
+
+	var storageProviderObj storage.StorageProvider
+	if !dryRun {
+		var err error
+		storageProviderObj, err = store.GetStorageProviderObj()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	storeId := store.GetId()
+	for _, file := range listFiles(store.FileTree) {
+		rule := EvaluateLifecycle(store, file)
+		if rule == nil || rule.Action != LifecycleActionExpire {
+			continue
+		}
+		if store.IsLocked(file) {
+			continue
+		}
+
+		affectedKeys = append(affectedKeys, file.Key)
+		if dryRun {
+			continue
+		}
+
+		if err := storageProviderObj.DeleteObject(file.Key); err != nil {
+			return affectedKeys, err
+		}
+
+		if _, err := DeleteVectorsForFile(storeId, file.Key); err != nil {
+			return affectedKeys, err
+		}
+	}
+
+	return affectedKeys, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifecycleFilterMatches(t *testing.T) {
+	file := &File{Key: "docs/report.pdf", Size: 500}
+	properties := &Properties{Subject: "finance"}
+
+	tests := []struct {
+		name   string
+		filter LifecycleFilter
+		want   bool
+	}{
+		{"empty filter matches everything", LifecycleFilter{}, true},
+		{"matching prefix", LifecycleFilter{Prefix: "docs/"}, true},
+		{"non-matching prefix", LifecycleFilter{Prefix: "images/"}, false},
+		{"size within range", LifecycleFilter{MinSize: 100, MaxSize: 1000}, true},
+		{"size below min", LifecycleFilter{MinSize: 1000}, false},
+		{"size above max", LifecycleFilter{MaxSize: 100}, false},
+		{"matching tag", LifecycleFilter{Tags: map[string]string{"subject": "finance"}}, true},
+		{"non-matching tag", LifecycleFilter{Tags: map[string]string{"subject": "legal"}}, false},
+		{"prefix AND tags both match", LifecycleFilter{Prefix: "docs/", Tags: map[string]string{"subject": "finance"}}, true},
+		{"prefix matches but tags don't", LifecycleFilter{Prefix: "docs/", Tags: map[string]string{"subject": "legal"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(file, properties); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateLifecycleFirstMatchWins(t *testing.T) {
+	store := &Store{
+		Owner: "admin",
+		Name:  "store1",
+		LifecycleConfig: &LifecycleConfig{
+			Rules: []*LifecycleRule{
+				{Id: "skip-docs", Filter: LifecycleFilter{Prefix: "docs/"}, Action: LifecycleActionSkipVectorize},
+				{Id: "expire-all", Filter: LifecycleFilter{}, Action: LifecycleActionExpire, Days: 1},
+			},
+		},
+	}
+
+	file := &File{Key: "docs/report.pdf", CreatedTime: time.Now().AddDate(0, 0, -30).Format(time.RFC3339)}
+
+	rule := EvaluateLifecycle(store, file)
+	if rule == nil || rule.Id != "skip-docs" {
+		t.Errorf("EvaluateLifecycle() = %v, want the first matching rule (skip-docs)", rule)
+	}
+}
+
+func TestEvaluateLifecycleExpireRespectsAge(t *testing.T) {
+	store := &Store{
+		Owner: "admin",
+		Name:  "store1",
+		LifecycleConfig: &LifecycleConfig{
+			Rules: []*LifecycleRule{
+				{Id: "expire-old", Filter: LifecycleFilter{}, Action: LifecycleActionExpire, Days: 30},
+			},
+		},
+	}
+
+	freshFile := &File{Key: "new.txt", CreatedTime: time.Now().Format(time.RFC3339)}
+	if rule := EvaluateLifecycle(store, freshFile); rule != nil {
+		t.Errorf("EvaluateLifecycle() = %v for a fresh file, want nil", rule)
+	}
+
+	oldFile := &File{Key: "old.txt", CreatedTime: time.Now().AddDate(0, 0, -60).Format(time.RFC3339)}
+	if rule := EvaluateLifecycle(store, oldFile); rule == nil || rule.Id != "expire-old" {
+		t.Errorf("EvaluateLifecycle() = %v for a file past its retention, want expire-old", rule)
+	}
+}
+
+func TestEvaluateLifecycleNoConfig(t *testing.T) {
+	store := &Store{Owner: "admin", Name: "store1"}
+	file := &File{Key: "any.txt"}
+
+	if rule := EvaluateLifecycle(store, file); rule != nil {
+		t.Errorf("EvaluateLifecycle() = %v for a store with no LifecycleConfig, want nil", rule)
+	}
+}
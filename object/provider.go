@@ -0,0 +1,257 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/casibase/casibase/embedding"
+	"github.com/casibase/casibase/storage"
+	"github.com/casibase/casibase/util"
+	"xorm.io/core"
+)
+
+// Provider is a configured backend a Store can delegate storage, model, or
+// embedding work to. Category picks which of those three roles it plays;
+// Type names the concrete backend (e.g. "S3", "Google Drive", "OpenAI").
+type Provider struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Category string `xorm:"varchar(100)" json:"category"`
+	Type     string `xorm:"varchar(100)" json:"type"`
+	SubType  string `xorm:"varchar(100)" json:"subType"`
+
+	ClientId     string `xorm:"varchar(100)" json:"clientId"`
+	ClientSecret string `xorm:"varchar(300)" json:"clientSecret"`
+	Region       string `xorm:"varchar(100)" json:"region"`
+	Bucket       string `xorm:"varchar(100)" json:"bucket"`
+	Endpoint     string `xorm:"varchar(300)" json:"endpoint"`
+
+	// AccessToken, RefreshToken and TokenExpiresAt persist the OAuth2 token for
+	// cloud-drive storage drivers (Google Drive, Dropbox, OneDrive). They stay
+	// empty for every other provider type, and are never serialized to the
+	// frontend.
+	AccessToken    string `xorm:"varchar(2000)" json:"-"`
+	RefreshToken   string `xorm:"varchar(2000)" json:"-"`
+	TokenExpiresAt string `xorm:"varchar(100)" json:"-"`
+}
+
+func GetProvider(id string) (*Provider, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	provider := Provider{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if existed {
+		return &provider, nil
+	}
+	return nil, nil
+}
+
+func getDefaultProviderForCategory(category string) (*Provider, error) {
+	providers := []*Provider{}
+	err := adapter.engine.Asc("owner").Desc("created_time").Find(&providers, &Provider{Category: category})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(providers) > 0 {
+		return providers[0], nil
+	}
+	return nil, nil
+}
+
+func GetDefaultStorageProvider() (*Provider, error) {
+	return getDefaultProviderForCategory("Storage")
+}
+
+func GetDefaultModelProvider() (*Provider, error) {
+	return getDefaultProviderForCategory("Model")
+}
+
+func GetDefaultEmbeddingProvider() (*Provider, error) {
+	return getDefaultProviderForCategory("Embedding")
+}
+
+func UpdateProvider(id string, provider *Provider) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	_, err := adapter.engine.ID(core.PK{owner, name}).AllCols().Update(provider)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *Provider) GetId() string {
+	return fmt.Sprintf("%s/%s", p.Owner, p.Name)
+}
+
+// GetStorageProviderObj builds the storage.StorageProvider this Provider
+// configures. For OAuth-based cloud drive drivers, it also loads the
+// persisted token into the driver and wraps it so a token refreshed during
+// use gets written back to this Provider's row.
+func (p *Provider) GetStorageProviderObj() (storage.StorageProvider, error) {
+	factory, err := storage.GetFactory(p.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	providerObj, err := factory(p.ClientId, p.ClientSecret, p.Region, p.Bucket, p.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthProviderObj, ok := providerObj.(storage.OAuthStorageProvider)
+	if !ok {
+		return providerObj, nil
+	}
+
+	token := &storage.OAuthToken{AccessToken: p.AccessToken, RefreshToken: p.RefreshToken}
+	if p.TokenExpiresAt != "" {
+		if expiresAt, err := time.Parse(time.RFC3339, p.TokenExpiresAt); err == nil {
+			token.ExpiresAt = expiresAt
+		}
+	}
+	oauthProviderObj.SetToken(token)
+
+	return &persistingOAuthProvider{OAuthStorageProvider: oauthProviderObj, provider: p}, nil
+}
+
+// GetOAuthAuthURL builds the URL the frontend should redirect the user to in
+// order to grant casibase access to this Provider's cloud drive, for
+// OAuth-based storage drivers (Google Drive, Dropbox, OneDrive). state is
+// echoed back unchanged by the provider in the callback, so the controller
+// handling it knows which Provider to attach the resulting token to.
+func (p *Provider) GetOAuthAuthURL(state string) (string, error) {
+	authorizer, err := p.getAuthorizer()
+	if err != nil {
+		return "", err
+	}
+
+	return authorizer.AuthURL(state), nil
+}
+
+// HandleOAuthCallback exchanges code for an access/refresh token pair and
+// persists it onto this Provider's row, so subsequent calls to
+// GetStorageProviderObj pick it up.
+func (p *Provider) HandleOAuthCallback(code string) error {
+	authorizer, err := p.getAuthorizer()
+	if err != nil {
+		return err
+	}
+
+	token, err := authorizer.HandleCallback(code)
+	if err != nil {
+		return err
+	}
+
+	p.AccessToken = token.AccessToken
+	p.RefreshToken = token.RefreshToken
+	p.TokenExpiresAt = token.ExpiresAt.Format(time.RFC3339)
+	_, err = UpdateProvider(p.GetId(), p)
+	return err
+}
+
+// getAuthorizer builds p's underlying storage driver and returns its
+// OAuthConfig, failing if p's Type isn't an OAuth-based storage driver.
+func (p *Provider) getAuthorizer() (*storage.OAuthConfig, error) {
+	factory, err := storage.GetFactory(p.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	providerObj, err := factory(p.ClientId, p.ClientSecret, p.Region, p.Bucket, p.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthProviderObj, ok := providerObj.(storage.OAuthStorageProvider)
+	if !ok {
+		return nil, fmt.Errorf("object: provider type %s does not use OAuth authorization", p.Type)
+	}
+
+	return oauthProviderObj.Authorizer(), nil
+}
+
+// GetEmbeddingProvider builds the embedding.EmbeddingProvider this Provider configures.
+func (p *Provider) GetEmbeddingProvider() (embedding.EmbeddingProvider, error) {
+	switch p.Type {
+	case "OpenAI":
+		return embedding.NewOpenAiProvider(p.ClientSecret, p.SubType), nil
+	default:
+		return nil, fmt.Errorf("object: unknown embedding provider type: %s", p.Type)
+	}
+}
+
+// persistingOAuthProvider wraps an storage.OAuthStorageProvider so that,
+// once an operation has transparently refreshed its token, the new token is
+// written back to the owning Provider row instead of being lost the next
+// time this Provider is loaded.
+type persistingOAuthProvider struct {
+	storage.OAuthStorageProvider
+	provider *Provider
+}
+
+func (w *persistingOAuthProvider) persistTokenIfChanged() error {
+	token := w.Token()
+	if token.AccessToken == w.provider.AccessToken && token.RefreshToken == w.provider.RefreshToken {
+		return nil
+	}
+
+	w.provider.AccessToken = token.AccessToken
+	w.provider.RefreshToken = token.RefreshToken
+	w.provider.TokenExpiresAt = token.ExpiresAt.Format(time.RFC3339)
+	_, err := UpdateProvider(w.provider.GetId(), w.provider)
+	return err
+}
+
+func (w *persistingOAuthProvider) ListObjects(prefix string) ([]*storage.Object, error) {
+	objects, err := w.OAuthStorageProvider.ListObjects(prefix)
+	if persistErr := w.persistTokenIfChanged(); persistErr != nil {
+		return objects, persistErr
+	}
+	return objects, err
+}
+
+func (w *persistingOAuthProvider) GetObject(key string) (io.ReadCloser, error) {
+	reader, err := w.OAuthStorageProvider.GetObject(key)
+	if persistErr := w.persistTokenIfChanged(); persistErr != nil {
+		return reader, persistErr
+	}
+	return reader, err
+}
+
+func (w *persistingOAuthProvider) PutObject(key string, reader io.Reader) error {
+	err := w.OAuthStorageProvider.PutObject(key, reader)
+	if persistErr := w.persistTokenIfChanged(); persistErr != nil {
+		return persistErr
+	}
+	return err
+}
+
+func (w *persistingOAuthProvider) DeleteObject(key string) error {
+	err := w.OAuthStorageProvider.DeleteObject(key)
+	if persistErr := w.persistTokenIfChanged(); persistErr != nil {
+		return persistErr
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// ScoredVector is one Vector returned by QueryStoreVectors, together with its
+// cosine similarity to the query.
+type ScoredVector struct {
+	Vector *Vector `json:"vector"`
+	Score  float64 `json:"score"`
+}
+
+// QueryStoreVectors embeds text and returns the topN most similar vectors in
+// the store identified by storeId, most similar first. Unlike the embed-time
+// filtering RefreshStoreVectors does with CanReadFile, this is the RAG query
+// path's own enforcement point: it re-checks subject against the live
+// enforcer for each candidate vector's source file (see canReadVector) rather than trusting
+// that the vector was only ever embedded for readable files, so a permission
+// revoked after embedding, or a query path that bypasses RefreshStoreVectors
+// entirely, still can't surface a file subject can no longer read.
+func QueryStoreVectors(ctx context.Context, store *Store, subject string, text string, topN int) ([]*ScoredVector, error) {
+	embeddingProvider, err := store.GetEmbeddingProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	embeddingProviderObj, err := embeddingProvider.GetEmbeddingProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	queryData, err := embeddingProviderObj.QueryVector(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := GetVectorsForStore(store.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	scored := make([]*ScoredVector, 0, len(vectors))
+	for _, vector := range vectors {
+		if !canReadVector(subject, vector) {
+			continue
+		}
+
+		scored = append(scored, &ScoredVector{Vector: vector, Score: cosineSimilarity(queryData, vector.Data)})
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	if topN > 0 && len(scored) > topN {
+		scored = scored[:topN]
+	}
+	return scored, nil
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if either
+// is empty or they differ in length.
+func cosineSimilarity(a []float32, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,283 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// RefreshProgress is one update emitted onto a refresh's progress channel as
+// RefreshStoreVectors works through a store's files.
+type RefreshProgress struct {
+	StoreId        string    `json:"storeId"`
+	FilesDiscoverd int       `json:"filesDiscovered"`
+	BytesEmbedded  int64     `json:"bytesEmbedded"`
+	CurrentKey     string    `json:"currentKey"`
+	Error          string    `json:"error,omitempty"`
+	Done           bool      `json:"done"`
+	UpdatedTime    time.Time `json:"updatedTime"`
+}
+
+// refreshRun tracks one in-flight or most recently finished refresh for a
+// store, and the set of callers currently subscribed to its progress.
+type refreshRun struct {
+	mutex       sync.Mutex
+	cancel      context.CancelFunc
+	running     bool
+	progress    *RefreshProgress
+	subscribers []chan *RefreshProgress
+}
+
+// subscribeLocked registers and returns a new progress channel for run,
+// whether a refresh is already in flight (the "join" case) or about to be
+// started. Callers must hold run.mutex.
+func (run *refreshRun) subscribeLocked() chan *RefreshProgress {
+	ch := make(chan *RefreshProgress, 16)
+	run.subscribers = append(run.subscribers, ch)
+	return ch
+}
+
+// broadcastLocked sends progress to every current subscriber, dropping it
+// for any subscriber whose buffer is full rather than blocking the refresh
+// on a slow or abandoned reader. Callers must hold run.mutex.
+func (run *refreshRun) broadcastLocked(progress *RefreshProgress) {
+	for _, ch := range run.subscribers {
+		select {
+		case ch <- progress:
+		default:
+		}
+	}
+}
+
+// closeSubscribersLocked closes every current subscriber channel and clears
+// the list, once a refresh has finished delivering its final progress.
+// Callers must hold run.mutex.
+func (run *refreshRun) closeSubscribersLocked() {
+	for _, ch := range run.subscribers {
+		close(ch)
+	}
+	run.subscribers = nil
+}
+
+// RefreshScheduler registers one cron entry per store whose Frequency > 0,
+// enforces each store's LimitMinutes as a hard per-run deadline, and
+// coalesces concurrent refreshes of the same store behind a per-store mutex.
+type RefreshScheduler struct {
+	cron *cron.Cron
+
+	runsMutex sync.Mutex
+	runs      map[string]*refreshRun
+}
+
+var globalRefreshScheduler = newRefreshScheduler()
+
+func newRefreshScheduler() *RefreshScheduler {
+	return &RefreshScheduler{
+		cron: cron.New(),
+		runs: map[string]*refreshRun{},
+	}
+}
+
+// sweepInterval is how often the lifecycle sweeper runs for a store that has
+// a LifecycleConfig, independent of that store's own refresh Frequency.
+const sweepInterval = "@every 24h"
+
+// InitRefreshScheduler registers a cron entry for every store with a
+// Frequency > 0, plus a lifecycle-sweep cron entry for every store with a
+// LifecycleConfig, and starts the scheduler. It should be called once at
+// server startup.
+func InitRefreshScheduler() error {
+	stores, err := GetGlobalStores()
+	if err != nil {
+		return err
+	}
+
+	for _, store := range stores {
+		if store.Frequency > 0 {
+			if err := globalRefreshScheduler.schedule(store); err != nil {
+				return err
+			}
+		}
+
+		if store.LifecycleConfig != nil {
+			if err := globalRefreshScheduler.scheduleSweep(store); err != nil {
+				return err
+			}
+		}
+	}
+
+	globalRefreshScheduler.cron.Start()
+	return nil
+}
+
+func (s *RefreshScheduler) schedule(store *Store) error {
+	spec := fmt.Sprintf("@every %dh", store.Frequency)
+	storeId := store.GetId()
+
+	_, err := s.cron.AddFunc(spec, func() {
+		// Scheduled runs have no logged-in user to filter by, so they embed
+		// every file regardless of ACL; only an interactively-triggered
+		// refresh (see controllers.RefreshStoreVectorsStream) carries a subject.
+		_, _ = RefreshStoreVectorsAsync(storeId, "")
+	})
+	return err
+}
+
+// scheduleSweep registers a recurring SweepExpiredFiles run for store, each
+// time re-fetching it from the database so the sweep always sees the
+// store's current FileTree and LifecycleConfig rather than a stale copy
+// captured at startup.
+func (s *RefreshScheduler) scheduleSweep(store *Store) error {
+	storeId := store.GetId()
+
+	_, err := s.cron.AddFunc(sweepInterval, func() {
+		current, err := GetStore(storeId)
+		if err != nil || current == nil {
+			return
+		}
+
+		_, _ = SweepExpiredFiles(current, false)
+	})
+	return err
+}
+
+func (s *RefreshScheduler) getOrCreateRun(storeId string) *refreshRun {
+	s.runsMutex.Lock()
+	defer s.runsMutex.Unlock()
+
+	run, ok := s.runs[storeId]
+	if !ok {
+		run = &refreshRun{}
+		s.runs[storeId] = run
+	}
+	return run
+}
+
+// RefreshStoreVectorsAsync starts (or joins, if one is already running) a
+// refresh of storeId and returns a progress channel live for the run: a
+// second caller while a refresh is in flight (e.g. the SSE endpoint after a
+// dropped connection reconnects) gets its own channel fed from the same
+// broadcast as every other subscriber, not an error. When the run completes,
+// the same final progress event is offered to every subscriber (dropped,
+// like any other, for one whose buffer is full) and every channel is closed.
+// LimitMinutes, if set on the store, is enforced as a hard deadline for the
+// whole run. subject, if non-empty, is threaded into RefreshStoreVectors so
+// only files that subject can read (per the Casbin enforcer, see
+// object.WithSubject) are embedded; joining callers do not affect the
+// subject an in-flight run was started with.
+func RefreshStoreVectorsAsync(storeId string, subject string) (<-chan *RefreshProgress, error) {
+	store, err := GetStore(storeId)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("object: store %s does not exist", storeId)
+	}
+
+	run := globalRefreshScheduler.getOrCreateRun(storeId)
+
+	run.mutex.Lock()
+	if run.running {
+		ch := run.subscribeLocked()
+		run.mutex.Unlock()
+		return ch, nil
+	}
+
+	run.running = true
+	run.progress = &RefreshProgress{StoreId: storeId, UpdatedTime: time.Now()}
+	progressCh := run.subscribeLocked()
+
+	ctx := WithSubject(context.Background(), subject)
+	if store.LimitMinutes > 0 {
+		ctx, run.cancel = context.WithTimeout(ctx, time.Duration(store.LimitMinutes)*time.Minute)
+	} else {
+		ctx, run.cancel = context.WithCancel(ctx)
+	}
+	run.mutex.Unlock()
+
+	go func() {
+		defer run.cancel()
+
+		internalCh := make(chan *RefreshProgress, 16)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for progress := range internalCh {
+				run.mutex.Lock()
+				run.progress = progress
+				run.broadcastLocked(progress)
+				run.mutex.Unlock()
+			}
+		}()
+
+		_, err := RefreshStoreVectors(ctx, store, internalCh)
+		close(internalCh)
+		<-done
+
+		run.mutex.Lock()
+		run.running = false
+		final := &RefreshProgress{StoreId: storeId, Done: true, UpdatedTime: time.Now()}
+		if err != nil {
+			final.Error = err.Error()
+		}
+		run.progress = final
+		run.broadcastLocked(final)
+		run.closeSubscribersLocked()
+		run.mutex.Unlock()
+	}()
+
+	return progressCh, nil
+}
+
+// CancelRefresh cancels the in-flight refresh for storeId, if any. Its
+// context is torn down, which propagates into the embedding provider's HTTP
+// calls so an in-flight batch stops cleanly instead of leaking goroutines.
+func CancelRefresh(storeId string) error {
+	globalRefreshScheduler.runsMutex.Lock()
+	run, ok := globalRefreshScheduler.runs[storeId]
+	globalRefreshScheduler.runsMutex.Unlock()
+	if !ok {
+		return fmt.Errorf("object: no refresh found for store %s", storeId)
+	}
+
+	run.mutex.Lock()
+	defer run.mutex.Unlock()
+	if !run.running || run.cancel == nil {
+		return fmt.Errorf("object: no refresh currently running for store %s", storeId)
+	}
+
+	run.cancel()
+	return nil
+}
+
+// GetRefreshStatus returns the most recent progress snapshot for storeId's
+// refresh, or nil if no refresh has ever run for it.
+func GetRefreshStatus(storeId string) *RefreshProgress {
+	globalRefreshScheduler.runsMutex.Lock()
+	run, ok := globalRefreshScheduler.runs[storeId]
+	globalRefreshScheduler.runsMutex.Unlock()
+	if !ok {
+		return nil
+	}
+
+	run.mutex.Lock()
+	defer run.mutex.Unlock()
+	return run.progress
+}
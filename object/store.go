@@ -15,7 +15,9 @@
 package object
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/casibase/casibase/storage"
 	"github.com/casibase/casibase/util"
@@ -49,6 +51,12 @@ type Store struct {
 	ModelProvider     string `xorm:"varchar(100)" json:"modelProvider"`
 	EmbeddingProvider string `xorm:"varchar(100)" json:"embeddingProvider"`
 
+	// RootFolderId is read only when StorageProvider is "casdoor-resource",
+	// where it carries "<application>/<prefix>". It is not passed to the cloud
+	// drive drivers (Google Drive, Dropbox, OneDrive), which take their root
+	// folder from their Provider's Bucket, nor to any other provider.
+	RootFolderId string `xorm:"varchar(100)" json:"rootFolderId"`
+
 	Frequency    int    `json:"frequency"`
 	LimitMinutes int    `json:"limitMinutes"`
 	Welcome      string `xorm:"varchar(100)" json:"welcome"`
@@ -56,6 +64,14 @@ type Store struct {
 
 	FileTree      *File                  `xorm:"mediumtext" json:"fileTree"`
 	PropertiesMap map[string]*Properties `xorm:"mediumtext" json:"propertiesMap"`
+
+	LifecycleConfig *LifecycleConfig `xorm:"mediumtext" json:"lifecycleConfig"`
+
+	// EncryptionConfig and ObjectLockConfig default to nil, meaning no
+	// server-side encryption and no retention lock, for every store created
+	// before this field existed.
+	EncryptionConfig *EncryptionConfig `xorm:"mediumtext" json:"encryptionConfig"`
+	ObjectLockConfig *ObjectLockConfig `xorm:"mediumtext" json:"objectLockConfig"`
 }
 
 func GetGlobalStores() ([]*Store, error) {
@@ -145,6 +161,22 @@ func AddStore(store *Store) (bool, error) {
 }
 
 func DeleteStore(store *Store) (bool, error) {
+	// Re-fetch from the DB rather than trusting store.FileTree: a caller may
+	// have passed in a Store whose FileTree was never hydrated (e.g. one
+	// built from just Owner/Name for a PK-only delete), and trusting that
+	// empty tree would silently skip the lock check below.
+	current, err := getStore(store.Owner, store.Name)
+	if err != nil {
+		return false, err
+	}
+	if current == nil {
+		return false, nil
+	}
+
+	if lockedKey := current.findLockedFile(); lockedKey != "" {
+		return false, &ErrObjectLocked{Key: lockedKey}
+	}
+
 	affected, err := adapter.engine.ID(core.PK{store.Owner, store.Name}).Delete(&Store{})
 	if err != nil {
 		return false, err
@@ -157,7 +189,21 @@ func (store *Store) GetId() string {
 	return fmt.Sprintf("%s/%s", store.Owner, store.Name)
 }
 
+// storageProviderCasdoorResource is the sentinel StorageProvider value that
+// backs a Store with Casdoor's own `resource` table instead of a configured
+// Provider row: store.RootFolderId then carries "<application>/<prefix>".
+const storageProviderCasdoorResource = "casdoor-resource"
+
 func (store *Store) GetStorageProviderObj() (storage.StorageProvider, error) {
+	if store.StorageProvider == storageProviderCasdoorResource {
+		application, prefix, _ := strings.Cut(store.RootFolderId, "/")
+		storageProviderObj, err := storage.NewCasdoorResourceProvider("", "", prefix, store.Owner+"/"+application, "")
+		if err != nil {
+			return nil, err
+		}
+		return wrapWithEncryption(wrapWithObjectLock(storageProviderObj, store), store.EncryptionConfig), nil
+	}
+
 	var provider *Provider
 	var err error
 	if store.StorageProvider == "" {
@@ -170,11 +216,17 @@ func (store *Store) GetStorageProviderObj() (storage.StorageProvider, error) {
 		return nil, err
 	}
 
+	var storageProviderObj storage.StorageProvider
 	if provider != nil {
-		return provider.GetStorageProviderObj()
+		storageProviderObj, err = provider.GetStorageProviderObj()
 	} else {
-		return storage.NewCasdoorProvider(store.StorageProvider)
+		storageProviderObj, err = storage.NewCasdoorProvider(store.StorageProvider)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapWithEncryption(wrapWithObjectLock(storageProviderObj, store), store.EncryptionConfig), nil
 }
 
 func (store *Store) GetModelProvider() (*Provider, error) {
@@ -195,7 +247,15 @@ func (store *Store) GetEmbeddingProvider() (*Provider, error) {
 	return GetProvider(providerId)
 }
 
-func RefreshStoreVectors(store *Store) (bool, error) {
+// RefreshStoreVectors re-embeds every file in store that isn't excluded by its
+// LifecycleConfig, reporting progress on progressCh as it goes. Passing a nil
+// progressCh is fine for callers that don't care about progress. ctx is
+// threaded into the embedding provider's HTTP calls, so canceling it (or its
+// deadline expiring, e.g. from Store.LimitMinutes) tears down an in-flight
+// batch instead of leaking goroutines. If ctx carries a subject (see
+// WithSubject), only files that subject is permitted to read are embedded,
+// and the ACL is attached to each resulting vector as metadata.
+func RefreshStoreVectors(ctx context.Context, store *Store, progressCh chan<- *RefreshProgress) (bool, error) {
 	storageProviderObj, err := store.GetStorageProviderObj()
 	if err != nil {
 		return false, err
@@ -221,6 +281,38 @@ func RefreshStoreVectors(store *Store) (bool, error) {
 		limit = 3
 	}
 
-	ok, err := addVectorsForStore(storageProviderObj, embeddingProviderObj, "", store.Name, embeddingProvider.Name, modelProvider.SubType, limit)
+	storeId := store.GetId()
+	if subject := SubjectFromContext(ctx); subject != "" && store.FileTree != nil {
+		store.FileTree.Children = filterFileTree(store.FileTree, func(file *File) bool {
+			return CanReadFile(subject, storeId, file.Key)
+		})
+	}
+
+	ok, err := addVectorsForStore(ctx, storageProviderObj, embeddingProviderObj, "", store, embeddingProvider.Name, modelProvider.SubType, limit, progressCh)
 	return ok, err
 }
+
+// filterFileTree returns file's children with every leaf that keep rejects
+// pruned out, recursing into subfolders and dropping folders left empty.
+func filterFileTree(file *File, keep func(*File) bool) []*File {
+	if file == nil {
+		return nil
+	}
+
+	kept := make([]*File, 0, len(file.Children))
+	for _, child := range file.Children {
+		if child.IsLeaf {
+			if keep(child) {
+				kept = append(kept, child)
+			}
+			continue
+		}
+
+		child.Children = filterFileTree(child, keep)
+		if len(child.Children) > 0 {
+			kept = append(kept, child)
+		}
+	}
+
+	return kept
+}
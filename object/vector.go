@@ -0,0 +1,65 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+// Vector is one embedded chunk of a Store's file, as produced by
+// RefreshStoreVectors/addVectorsForStore.
+type Vector struct {
+	Owner string    `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name  string    `xorm:"varchar(100) notnull pk" json:"name"`
+	Store string    `xorm:"varchar(100) index" json:"store"`
+	File  string    `xorm:"varchar(100) index" json:"file"`
+	Data  []float32 `xorm:"mediumtext" json:"data"`
+
+	// Acl is the list of subjects permitted to read this vector's source
+	// file, snapshotted at embed time by RefreshStoreVectors.
+	Acl []string `xorm:"mediumtext" json:"acl"`
+}
+
+// DeleteVectorsForFile deletes every vector embedded from the file at
+// fileKey in the store identified by storeId, e.g. once that file has
+// expired under the store's LifecycleConfig.
+func DeleteVectorsForFile(storeId string, fileKey string) (int64, error) {
+	return adapter.engine.Delete(&Vector{Store: storeId, File: fileKey})
+}
+
+// AddVector upserts vector, replacing any existing vector with the same
+// Owner/Name (re-embedding a file overwrites its previous vector rather than
+// accumulating duplicates).
+func AddVector(vector *Vector) (bool, error) {
+	_, err := adapter.engine.Delete(&Vector{Owner: vector.Owner, Name: vector.Name})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := adapter.engine.Insert(vector)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+// GetVectorsForStore returns every vector embedded for the store identified
+// by storeId, for use by the query-time ACL re-check in QueryStoreVectors.
+func GetVectorsForStore(storeId string) ([]*Vector, error) {
+	vectors := []*Vector{}
+	err := adapter.engine.Find(&vectors, &Vector{Store: storeId})
+	if err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/casibase/casibase/embedding"
+	"github.com/casibase/casibase/storage"
+)
+
+// addVectorsForStore walks storageProviderObj (rooted at prefix) and embeds
+// every file via embeddingProviderObj, up to limit files, reporting progress
+// on progressCh (which may be nil) as it goes. Each embedding is persisted as
+// a Vector (replacing any prior vector for the same file) with the file's
+// current ACL snapshotted onto it via aclForFile as metadata. If ctx carries
+// a subject (see WithSubject), files that subject cannot read are skipped.
+// ctx bounds the whole run: canceling it, directly or via the deadline
+// RefreshStoreVectorsAsync derives from Store.LimitMinutes, is passed into
+// every embedding HTTP call so an in-flight one is torn down instead of
+// leaking a goroutine.
+func addVectorsForStore(ctx context.Context, storageProviderObj storage.StorageProvider, embeddingProviderObj embedding.EmbeddingProvider, prefix string, store *Store, embeddingProviderName string, modelSubType string, limit int, progressCh chan<- *RefreshProgress) (bool, error) {
+	objects, err := storageProviderObj.ListObjects(prefix)
+	if err != nil {
+		return false, err
+	}
+
+	progress := &RefreshProgress{StoreId: store.GetId(), UpdatedTime: time.Now()}
+	emitProgress(progressCh, progress)
+
+	count := 0
+	for _, obj := range objects {
+		if !obj.IsLeaf {
+			continue
+		}
+		if count >= limit {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		file := &File{Key: obj.Key, Size: obj.Size, IsLeaf: true, CreatedTime: obj.CreatedTime}
+		if rule := EvaluateLifecycle(store, file); rule != nil && rule.Action == LifecycleActionSkipVectorize {
+			continue
+		}
+		if subject := SubjectFromContext(ctx); subject != "" && !CanReadFile(subject, store.GetId(), obj.Key) {
+			continue
+		}
+
+		content, err := readObject(storageProviderObj, obj.Key)
+		if err != nil {
+			progress.Error = err.Error()
+			emitProgress(progressCh, progress)
+			return false, err
+		}
+
+		data, err := embeddingProviderObj.QueryVector(ctx, string(content))
+		if err != nil {
+			progress.Error = err.Error()
+			emitProgress(progressCh, progress)
+			return false, err
+		}
+
+		storeId := store.GetId()
+		vector := &Vector{
+			Owner: store.Owner,
+			Name:  vectorName(storeId, obj.Key),
+			Store: storeId,
+			File:  obj.Key,
+			Data:  data,
+			Acl:   aclForFile(storeId, obj.Key),
+		}
+		if _, err := AddVector(vector); err != nil {
+			progress.Error = err.Error()
+			emitProgress(progressCh, progress)
+			return false, err
+		}
+
+		count++
+		progress.FilesDiscoverd = count
+		progress.BytesEmbedded += int64(len(content))
+		progress.CurrentKey = obj.Key
+		progress.UpdatedTime = time.Now()
+		emitProgress(progressCh, progress)
+	}
+
+	return true, nil
+}
+
+// vectorName derives a stable, unique Vector.Name from storeId and fileKey,
+// so re-embedding the same file overwrites its previous vector (see
+// AddVector) instead of accumulating duplicates under a fresh random name.
+func vectorName(storeId string, fileKey string) string {
+	replacer := strings.NewReplacer("/", "_", ":", "_")
+	return replacer.Replace(storeId) + "_" + replacer.Replace(fileKey)
+}
+
+func readObject(storageProviderObj storage.StorageProvider, key string) ([]byte, error) {
+	reader, err := storageProviderObj.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// emitProgress sends progress on progressCh without blocking the caller if
+// nothing is currently receiving, and is a no-op when progressCh is nil.
+func emitProgress(progressCh chan<- *RefreshProgress, progress *RefreshProgress) {
+	if progressCh == nil {
+		return
+	}
+
+	select {
+	case progressCh <- progress:
+	default:
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+// httpGet downloads url and returns its body, leaving the caller responsible
+// for closing it.
+func httpGet(url string) (io.ReadCloser, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("storage: GET %s failed with status %d: %s", url, resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
+// CasdoorResourceProvider backs a Store with rows of Casdoor's own `resource`
+// table instead of an external object store, mirroring the
+// ListResources/GetResource pattern used by Casnode. bucket carries
+// "organization/application" and prefix narrows it down further to a key
+// prefix within that application's uploads.
+type CasdoorResourceProvider struct {
+	owner       string
+	application string
+	prefix      string
+}
+
+// NewCasdoorResourceProvider is registered under the "casdoor-resource"
+// driver name. bucket is "<organization>/<application>"; region is a key
+// prefix filter (for a "casdoor-resource" Store, the part of its
+// RootFolderId after the application name).
+func NewCasdoorResourceProvider(clientId string, clientSecret string, region string, bucket string, endpoint string) (StorageProvider, error) {
+	owner, application, found := strings.Cut(bucket, "/")
+	if !found {
+		return nil, fmt.Errorf("storage: casdoor-resource bucket must be \"organization/application\", got: %s", bucket)
+	}
+
+	return &CasdoorResourceProvider{owner: owner, application: application, prefix: region}, nil
+}
+
+func init() {
+	Register("casdoor-resource", NewCasdoorResourceProvider)
+}
+
+// ListObjects lists every resource row for the provider's organization and
+// application whose name starts with prefix (joined with the store's own prefix).
+func (p *CasdoorResourceProvider) ListObjects(prefix string) ([]*Object, error) {
+	resources, err := casdoorsdk.GetResources(p.owner, "", "", "", "", p.application, "")
+	if err != nil {
+		return nil, err
+	}
+
+	fullPrefix := p.prefix + prefix
+	objects := []*Object{}
+	for _, resource := range resources {
+		if fullPrefix != "" && !strings.HasPrefix(resource.Name, fullPrefix) {
+			continue
+		}
+
+		objects = append(objects, &Object{
+			Key:         resource.Name,
+			Size:        int64(resource.FileSize),
+			IsLeaf:      true,
+			CreatedTime: resource.CreatedTime,
+		})
+	}
+
+	return objects, nil
+}
+
+// GetObject fetches the resource named key and downloads its content from
+// its stored URL.
+func (p *CasdoorResourceProvider) GetObject(key string) (io.ReadCloser, error) {
+	resource, err := casdoorsdk.GetResource(p.owner, key)
+	if err != nil {
+		return nil, err
+	}
+	if resource == nil {
+		return nil, fmt.Errorf("storage: casdoor resource not found: %s", key)
+	}
+
+	resp, err := httpGet(resource.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// PutObject uploads reader's content as a new resource row named key.
+func (p *CasdoorResourceProvider) PutObject(key string, reader io.Reader) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = casdoorsdk.UploadResource(p.owner, key, "", key, bytes.NewReader(content))
+	return err
+}
+
+// DeleteObject deletes the resource row named key.
+func (p *CasdoorResourceProvider) DeleteObject(key string) error {
+	resource, err := casdoorsdk.GetResource(p.owner, key)
+	if err != nil {
+		return err
+	}
+	if resource == nil {
+		return nil
+	}
+
+	_, err = casdoorsdk.DeleteResource(resource)
+	return err
+}
@@ -0,0 +1,245 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+)
+
+func init() {
+	Register("Dropbox", NewDropboxProvider)
+}
+
+// DropboxProvider backs a Store with a user's Dropbox, via the `/2/files`
+// endpoints. The Provider's Bucket is interpreted as the root Dropbox path
+// (e.g. "/casibase"), with "" meaning the app's root.
+type DropboxProvider struct {
+	oauth    *OAuthConfig
+	token    *OAuthToken
+	rootPath string
+	client   *http.Client
+}
+
+func NewDropboxProvider(clientId string, clientSecret string, region string, bucket string, endpoint string) (StorageProvider, error) {
+	return &DropboxProvider{
+		oauth: &OAuthConfig{
+			ClientId:     clientId,
+			ClientSecret: clientSecret,
+			RedirectUri:  endpoint,
+			AuthUrl:      "https://www.dropbox.com/oauth2/authorize",
+			TokenUrl:     "https://api.dropboxapi.com/oauth2/token",
+		},
+		rootPath: bucket,
+		client:   &http.Client{},
+	}, nil
+}
+
+func (p *DropboxProvider) SetToken(token *OAuthToken) {
+	p.token = token
+}
+
+func (p *DropboxProvider) Token() *OAuthToken {
+	return p.token
+}
+
+func (p *DropboxProvider) Authorizer() *OAuthConfig {
+	return p.oauth
+}
+
+func (p *DropboxProvider) authedRequest(rawUrl string, payload interface{}) (*http.Request, error) {
+	if err := p.oauth.EnsureFresh(p.token); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, rawUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (p *DropboxProvider) resolvePath(prefix string) string {
+	if prefix == "" {
+		return p.rootPath
+	}
+	return path.Join(p.rootPath, prefix)
+}
+
+type dropboxEntry struct {
+	Tag  string `json:".tag"`
+	Name string `json:"name"`
+	Path string `json:"path_display"`
+	Size int64  `json:"size"`
+}
+
+// ListObjects lists the entries under prefix, recursively, using Dropbox's
+// `list_folder` endpoint and following `list_folder/continue` until
+// `has_more` is false.
+func (p *DropboxProvider) ListObjects(prefix string) ([]*Object, error) {
+	req, err := p.authedRequest("https://api.dropboxapi.com/2/files/list_folder", map[string]interface{}{
+		"path":      p.resolvePath(prefix),
+		"recursive": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []*Object{}
+	for {
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("storage: dropbox ListObjects failed with status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var res struct {
+			Entries []dropboxEntry `json:"entries"`
+			Cursor  string         `json:"cursor"`
+			HasMore bool           `json:"has_more"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range res.Entries {
+			objects = append(objects, &Object{
+				Key:    entry.Path,
+				Size:   entry.Size,
+				IsLeaf: entry.Tag == "file",
+			})
+		}
+
+		if !res.HasMore {
+			break
+		}
+
+		req, err = p.authedRequest("https://api.dropboxapi.com/2/files/list_folder/continue", map[string]interface{}{
+			"cursor": res.Cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+// GetObject downloads the content of the file at the given Dropbox path.
+func (p *DropboxProvider) GetObject(key string) (io.ReadCloser, error) {
+	if err := p.oauth.EnsureFresh(p.token); err != nil {
+		return nil, err
+	}
+
+	args, err := json.Marshal(map[string]string{"path": key})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://content.dropboxapi.com/2/files/download", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token.AccessToken)
+	req.Header.Set("Dropbox-API-Arg", string(args))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("storage: dropbox GetObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
+}
+
+// PutObject uploads reader's content to key, resolved under the root path,
+// overwriting any existing file.
+func (p *DropboxProvider) PutObject(key string, reader io.Reader) error {
+	if err := p.oauth.EnsureFresh(p.token); err != nil {
+		return err
+	}
+
+	args, err := json.Marshal(map[string]interface{}{
+		"path": p.resolvePath(key),
+		"mode": "overwrite",
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://content.dropboxapi.com/2/files/upload", reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token.AccessToken)
+	req.Header.Set("Dropbox-API-Arg", string(args))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storage: dropbox PutObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// DeleteObject deletes the file or folder at the given Dropbox path.
+func (p *DropboxProvider) DeleteObject(key string) error {
+	req, err := p.authedRequest("https://api.dropboxapi.com/2/files/delete_v2", map[string]string{"path": key})
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storage: dropbox DeleteObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
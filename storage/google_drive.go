@@ -0,0 +1,253 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	Register("Google Drive", NewGoogleDriveProvider)
+}
+
+// GoogleDriveProvider backs a Store with a user's Google Drive, via the
+// `files` resource of the Drive v3 REST API. The Provider's Bucket is the
+// Drive folder ID to treat as the root; an empty value means "My Drive".
+type GoogleDriveProvider struct {
+	oauth        *OAuthConfig
+	token        *OAuthToken
+	rootFolderId string
+	client       *http.Client
+}
+
+func NewGoogleDriveProvider(clientId string, clientSecret string, region string, bucket string, endpoint string) (StorageProvider, error) {
+	return &GoogleDriveProvider{
+		oauth: &OAuthConfig{
+			ClientId:     clientId,
+			ClientSecret: clientSecret,
+			RedirectUri:  endpoint,
+			AuthUrl:      "https://accounts.google.com/o/oauth2/v2/auth",
+			TokenUrl:     "https://oauth2.googleapis.com/token",
+			Scopes:       []string{"https://www.googleapis.com/auth/drive"},
+		},
+		rootFolderId: bucket,
+		client:       &http.Client{},
+	}, nil
+}
+
+func (p *GoogleDriveProvider) SetToken(token *OAuthToken) {
+	p.token = token
+}
+
+func (p *GoogleDriveProvider) Token() *OAuthToken {
+	return p.token
+}
+
+func (p *GoogleDriveProvider) Authorizer() *OAuthConfig {
+	return p.oauth
+}
+
+func (p *GoogleDriveProvider) authedRequest(method string, rawUrl string, body io.Reader) (*http.Request, error) {
+	if err := p.oauth.EnsureFresh(p.token); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, rawUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token.AccessToken)
+	return req, nil
+}
+
+type googleDriveFile struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	MimeType     string `json:"mimeType"`
+	Size         string `json:"size"`
+	ModifiedTime string `json:"modifiedTime"`
+}
+
+// ListObjects lists the files directly under prefix (a Drive folder ID, or
+// the root folder ID if prefix is empty), paging through nextPageToken
+// until Drive reports none left, and recursing into subfolders.
+func (p *GoogleDriveProvider) ListObjects(prefix string) ([]*Object, error) {
+	folderId := prefix
+	if folderId == "" {
+		folderId = p.rootFolderId
+	}
+	if folderId == "" {
+		folderId = "root"
+	}
+
+	objects := []*Object{}
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("q", fmt.Sprintf("'%s' in parents and trashed = false", folderId))
+		query.Set("fields", "nextPageToken,files(id,name,mimeType,size,modifiedTime)")
+		query.Set("pageSize", "1000")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		rawUrl := "https://www.googleapis.com/drive/v3/files?" + query.Encode()
+
+		req, err := p.authedRequest(http.MethodGet, rawUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("storage: google drive ListObjects failed with status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var res struct {
+			NextPageToken string            `json:"nextPageToken"`
+			Files         []googleDriveFile `json:"files"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range res.Files {
+			isFolder := file.MimeType == "application/vnd.google-apps.folder"
+			var size int64
+			_, _ = fmt.Sscan(file.Size, &size)
+
+			objects = append(objects, &Object{
+				Key:         file.Id,
+				Size:        size,
+				IsLeaf:      !isFolder,
+				CreatedTime: file.ModifiedTime,
+			})
+
+			if isFolder {
+				children, err := p.ListObjects(file.Id)
+				if err != nil {
+					return nil, err
+				}
+				objects = append(objects, children...)
+			}
+		}
+
+		if res.NextPageToken == "" {
+			break
+		}
+		pageToken = res.NextPageToken
+	}
+
+	return objects, nil
+}
+
+// GetObject downloads the content of the file with the given Drive file ID.
+func (p *GoogleDriveProvider) GetObject(key string) (io.ReadCloser, error) {
+	rawUrl := fmt.Sprintf("https://www.googleapis.com/drive/v3/files/%s?alt=media", key)
+	req, err := p.authedRequest(http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("storage: google drive GetObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
+}
+
+// PutObject uploads reader's content as a new file named key under the
+// store's root folder, via Drive's multipart upload so name and parent can
+// be set in the same request as the content.
+func (p *GoogleDriveProvider) PutObject(key string, reader io.Reader) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(map[string]interface{}{
+		"name":    key,
+		"parents": []string{p.rootFolderId},
+	})
+	if err != nil {
+		return err
+	}
+
+	boundary := "casibase-drive-upload"
+	body := fmt.Sprintf(
+		"--%s\r\nContent-Type: application/json; charset=UTF-8\r\n\r\n%s\r\n--%s\r\nContent-Type: application/octet-stream\r\n\r\n%s\r\n--%s--",
+		boundary, string(metadata), boundary, string(content), boundary,
+	)
+
+	rawUrl := "https://www.googleapis.com/upload/drive/v3/files?uploadType=multipart&fields=id"
+	req, err := p.authedRequest(http.MethodPost, rawUrl, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+boundary)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storage: google drive PutObject failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// DeleteObject deletes the file with the given Drive file ID.
+func (p *GoogleDriveProvider) DeleteObject(key string) error {
+	rawUrl := fmt.Sprintf("https://www.googleapis.com/drive/v3/files/%s", key)
+	req, err := p.authedRequest(http.MethodDelete, rawUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storage: google drive DeleteObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
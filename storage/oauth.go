@@ -0,0 +1,159 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// OAuthToken is the access/refresh token pair a cloud drive driver persists
+// on the owning Provider's AccessToken/RefreshToken fields.
+type OAuthToken struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// Expired reports whether the access token needs to be refreshed before the
+// next API call, with a minute of slack to avoid racing the provider's clock.
+func (t *OAuthToken) Expired() bool {
+	return t.AccessToken == "" || time.Now().After(t.ExpiresAt.Add(-time.Minute))
+}
+
+// OAuthConfig describes the endpoints and client credentials used to drive
+// the authorization-code flow for one OAuth-based storage driver (Google
+// Drive, Dropbox, OneDrive).
+type OAuthConfig struct {
+	ClientId     string
+	ClientSecret string
+	RedirectUri  string
+	AuthUrl      string
+	TokenUrl     string
+	Scopes       []string
+}
+
+// AuthURL builds the URL the frontend should redirect the user to in order
+// to grant casibase access to their cloud drive. state is echoed back
+// unchanged to HandleCallback's caller so the provider being authorized can
+// be identified.
+func (c *OAuthConfig) AuthURL(state string) string {
+	values := url.Values{}
+	values.Set("client_id", c.ClientId)
+	values.Set("redirect_uri", c.RedirectUri)
+	values.Set("response_type", "code")
+	values.Set("access_type", "offline")
+	values.Set("state", state)
+	if len(c.Scopes) > 0 {
+		scopes := ""
+		for i, scope := range c.Scopes {
+			if i > 0 {
+				scopes += " "
+			}
+			scopes += scope
+		}
+		values.Set("scope", scopes)
+	}
+
+	return fmt.Sprintf("%s?%s", c.AuthUrl, values.Encode())
+}
+
+// HandleCallback exchanges the authorization code returned in the OAuth
+// callback for an access/refresh token pair.
+func (c *OAuthConfig) HandleCallback(code string) (*OAuthToken, error) {
+	values := url.Values{}
+	values.Set("client_id", c.ClientId)
+	values.Set("client_secret", c.ClientSecret)
+	values.Set("redirect_uri", c.RedirectUri)
+	values.Set("code", code)
+	values.Set("grant_type", "authorization_code")
+
+	return c.exchange(values)
+}
+
+// Refresh exchanges token's refresh token for a new access token, mutating
+// token in place. Callers should persist the Provider after calling this.
+func (c *OAuthConfig) Refresh(token *OAuthToken) error {
+	values := url.Values{}
+	values.Set("client_id", c.ClientId)
+	values.Set("client_secret", c.ClientSecret)
+	values.Set("refresh_token", token.RefreshToken)
+	values.Set("grant_type", "refresh_token")
+
+	newToken, err := c.exchange(values)
+	if err != nil {
+		return err
+	}
+
+	token.AccessToken = newToken.AccessToken
+	token.ExpiresAt = newToken.ExpiresAt
+	if newToken.RefreshToken != "" {
+		token.RefreshToken = newToken.RefreshToken
+	}
+	return nil
+}
+
+// EnsureFresh refreshes token if it is expired or about to expire. token must
+// have been installed with SetToken first; a nil token means the driver is
+// being used before its OAuth credentials were loaded.
+func (c *OAuthConfig) EnsureFresh(token *OAuthToken) error {
+	if token == nil {
+		return fmt.Errorf("storage: oauth token not set, call SetToken first")
+	}
+
+	if !token.Expired() {
+		return nil
+	}
+
+	return c.Refresh(token)
+}
+
+func (c *OAuthConfig) exchange(values url.Values) (*OAuthToken, error) {
+	resp, err := http.PostForm(c.TokenUrl, values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("storage: oauth token exchange failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var res struct {
+		AccessToken  string      `json:"access_token"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+
+	expiresIn, _ := strconv.Atoi(res.ExpiresIn.String())
+	return &OAuthToken{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOAuthTokenExpired(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *OAuthToken
+		want  bool
+	}{
+		{"no access token", &OAuthToken{}, true},
+		{"far in the future", &OAuthToken{AccessToken: "t", ExpiresAt: time.Now().Add(time.Hour)}, false},
+		{"already past", &OAuthToken{AccessToken: "t", ExpiresAt: time.Now().Add(-time.Minute)}, true},
+		{"within the refresh slack window", &OAuthToken{AccessToken: "t", ExpiresAt: time.Now().Add(30 * time.Second)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.Expired(); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
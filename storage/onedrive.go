@@ -0,0 +1,218 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+func init() {
+	Register("OneDrive", NewOneDriveProvider)
+}
+
+// OneDriveProvider backs a Store with a user's OneDrive, via the Microsoft
+// Graph `/me/drive` endpoints. The Provider's Bucket is the root Graph drive
+// item ID; "" means the drive's root item.
+type OneDriveProvider struct {
+	oauth        *OAuthConfig
+	token        *OAuthToken
+	rootFolderId string
+	client       *http.Client
+}
+
+func NewOneDriveProvider(clientId string, clientSecret string, region string, bucket string, endpoint string) (StorageProvider, error) {
+	return &OneDriveProvider{
+		oauth: &OAuthConfig{
+			ClientId:     clientId,
+			ClientSecret: clientSecret,
+			RedirectUri:  endpoint,
+			AuthUrl:      "https://login.microsoftonline.com/common/oauth2/v2.0/authorize",
+			TokenUrl:     "https://login.microsoftonline.com/common/oauth2/v2.0/token",
+			Scopes:       []string{"Files.ReadWrite", "offline_access"},
+		},
+		rootFolderId: bucket,
+		client:       &http.Client{},
+	}, nil
+}
+
+func (p *OneDriveProvider) SetToken(token *OAuthToken) {
+	p.token = token
+}
+
+func (p *OneDriveProvider) Token() *OAuthToken {
+	return p.token
+}
+
+func (p *OneDriveProvider) Authorizer() *OAuthConfig {
+	return p.oauth
+}
+
+func (p *OneDriveProvider) authedRequest(method string, rawUrl string, body io.Reader) (*http.Request, error) {
+	if err := p.oauth.EnsureFresh(p.token); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, rawUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token.AccessToken)
+	return req, nil
+}
+
+func (p *OneDriveProvider) itemUrl(itemId string) string {
+	if itemId == "" {
+		return "https://graph.microsoft.com/v1.0/me/drive/root"
+	}
+	return fmt.Sprintf("https://graph.microsoft.com/v1.0/me/drive/items/%s", itemId)
+}
+
+type oneDriveItem struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Folder *struct {
+		ChildCount int `json:"childCount"`
+	} `json:"folder"`
+}
+
+// ListObjects lists the children directly under prefix (a Graph item ID, or
+// the root folder ID if prefix is empty), following `@odata.nextLink`
+// until Graph reports none left, and recursing into subfolders.
+func (p *OneDriveProvider) ListObjects(prefix string) ([]*Object, error) {
+	folderId := prefix
+	if folderId == "" {
+		folderId = p.rootFolderId
+	}
+
+	objects := []*Object{}
+	rawUrl := p.itemUrl(folderId) + "/children?$top=999"
+	for rawUrl != "" {
+		req, err := p.authedRequest(http.MethodGet, rawUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("storage: onedrive ListObjects failed with status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var res struct {
+			Value    []oneDriveItem `json:"value"`
+			NextLink string         `json:"@odata.nextLink"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range res.Value {
+			isFolder := item.Folder != nil
+			objects = append(objects, &Object{
+				Key:    item.Id,
+				Size:   item.Size,
+				IsLeaf: !isFolder,
+			})
+
+			if isFolder {
+				children, err := p.ListObjects(item.Id)
+				if err != nil {
+					return nil, err
+				}
+				objects = append(objects, children...)
+			}
+		}
+
+		rawUrl = res.NextLink
+	}
+
+	return objects, nil
+}
+
+// GetObject downloads the content of the file with the given Graph item ID.
+func (p *OneDriveProvider) GetObject(key string) (io.ReadCloser, error) {
+	req, err := p.authedRequest(http.MethodGet, p.itemUrl(key)+"/content", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("storage: onedrive GetObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
+}
+
+// PutObject uploads reader's content as a new file named key under the
+// store's root folder.
+func (p *OneDriveProvider) PutObject(key string, reader io.Reader) error {
+	rawUrl := fmt.Sprintf("%s:/%s:/content", p.itemUrl(p.rootFolderId), key)
+	req, err := p.authedRequest(http.MethodPut, rawUrl, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storage: onedrive PutObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// DeleteObject deletes the file with the given Graph item ID.
+func (p *OneDriveProvider) DeleteObject(key string) error {
+	req, err := p.authedRequest(http.MethodDelete, p.itemUrl(key), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storage: onedrive DeleteObject failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "io"
+
+// StorageProvider is implemented by every storage backend a Store can read
+// files from and write vectors' source files against: S3-compatible object
+// storage as well as OAuth-based cloud drives (Google Drive, Dropbox,
+// OneDrive). The rest of casibase only talks to this interface, so adding a
+// new backend never requires touching the embedding pipeline.
+type StorageProvider interface {
+	// ListObjects lists all objects under prefix, recursively.
+	ListObjects(prefix string) ([]*Object, error)
+	// GetObject streams the content of the object at key.
+	GetObject(key string) (io.ReadCloser, error)
+	// PutObject uploads reader's content to key.
+	PutObject(key string, reader io.Reader) error
+	// DeleteObject removes the object at key.
+	DeleteObject(key string) error
+}
+
+// Object is a single file or folder as reported by a StorageProvider, before
+// it is mapped into the object.File tree used by RefreshStoreVectors.
+type Object struct {
+	Key         string
+	Size        int64
+	IsLeaf      bool
+	CreatedTime string
+}
+
+// OAuthStorageProvider is additionally implemented by cloud drive drivers
+// whose credentials are an OAuth2 token rather than a static access key, so
+// the Provider object that owns them can inject and persist a refreshed
+// token without the caller needing to know which driver it is.
+type OAuthStorageProvider interface {
+	StorageProvider
+
+	// SetToken installs the token the driver should authenticate with.
+	SetToken(token *OAuthToken)
+	// Token returns the driver's current token, e.g. after it auto-refreshed.
+	Token() *OAuthToken
+	// Authorizer returns the OAuthConfig driving this driver's authorization
+	// flow, so a caller that never needs an authenticated request (building
+	// the consent URL, exchanging an authorization code) doesn't need to
+	// know which driver it's talking to either.
+	Authorizer() *OAuthConfig
+}
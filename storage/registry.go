@@ -0,0 +1,50 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "fmt"
+
+// Factory builds a StorageProvider for one driver from the credentials
+// stored on a Provider record. OAuth-based drivers receive their token
+// afterwards, via OAuthStorageProvider.SetToken.
+type Factory func(clientId string, clientSecret string, region string, bucket string, endpoint string) (StorageProvider, error)
+
+var driverRegistry = map[string]Factory{}
+
+// Register adds a storage driver factory under the given name, so it can
+// later be looked up by Provider.Type. Drivers call this from an init()
+// function, e.g.: storage.Register("Google Drive", NewGoogleDriveProvider).
+func Register(name string, factory Factory) {
+	driverRegistry[name] = factory
+}
+
+// List returns the names of all registered storage drivers.
+func List() []string {
+	names := make([]string, 0, len(driverRegistry))
+	for name := range driverRegistry {
+		names = append(names, name)
+	}
+	return names
+}
+
+// GetFactory looks up the driver factory registered under name.
+func GetFactory(name string) (Factory, error) {
+	factory, ok := driverRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("storage: no driver registered for type: %s", name)
+	}
+
+	return factory, nil
+}